@@ -0,0 +1,452 @@
+package influxdb
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchConfig controls the batching, retry and hinted-handoff behavior
+// of a BatchWriter.
+type BatchConfig struct {
+	// MaxBatchSize is the number of points buffered before a batch is
+	// flushed early, regardless of FlushInterval.
+	MaxBatchSize int
+
+	// FlushInterval is the maximum amount of time points are held
+	// before being flushed to the server.
+	FlushInterval time.Duration
+
+	// MaxRetries is the number of times a failed batch is retried with
+	// exponential backoff before it is spooled to BufferDir.
+	MaxRetries int
+
+	// RetryInterval is the initial delay between retries; it doubles
+	// after each attempt.
+	RetryInterval time.Duration
+
+	// BufferDir is the directory spooled batches are written to when
+	// the server cannot be reached after MaxRetries retries. If empty,
+	// batches that exhaust their retries are dropped instead.
+	BufferDir string
+}
+
+// BatchMetrics reports cumulative counters for a BatchWriter.
+type BatchMetrics struct {
+	PointsWritten  uint64
+	PointsDropped  uint64
+	BatchesSpooled uint64
+	LastError      error
+}
+
+// batchKey groups points by the retention policy they should be
+// written to; the empty string means the database's default policy.
+type batchKey string
+
+// BatchWriter coalesces points written with Add into batches and writes
+// them to a Client in the background. Transient failures are retried
+// with exponential backoff; once MaxRetries is exhausted a batch is
+// spooled to disk as gzipped JSON and replayed once the server
+// recovers, giving callers the "hinted handoff" behavior of the
+// InfluxDB write path.
+type BatchWriter struct {
+	client *Client
+	config BatchConfig
+
+	addCh   chan batchPoint
+	flushCh chan chan struct{}
+	closeCh chan struct{}
+	doneCh  chan struct{}
+
+	// sendQueue/inFlight hand completed batches from run() to sendLoop,
+	// which is the only goroutine that ever blocks on retry backoff.
+	// Queueing here (rather than calling writeBatch from run()) keeps
+	// Add non-blocking even while the sender is backing off a down
+	// server: run() keeps draining addCh into buckets instead of
+	// stalling inside a flush.
+	sendMu     sync.Mutex
+	sendCond   *sync.Cond
+	sendQueue  []pendingBatch
+	inFlight   int
+	stopped    bool
+	senderDone chan struct{}
+
+	pointsWritten  uint64
+	pointsDropped  uint64
+	batchesSpooled uint64
+
+	errMu   sync.Mutex
+	lastErr error
+}
+
+type batchPoint struct {
+	key             batchKey
+	retentionPolicy string
+	series          *Series
+}
+
+type pendingBatch struct {
+	retentionPolicy string
+	series          []*Series
+}
+
+// NewBatchWriter creates a BatchWriter on top of client using config. It
+// starts the background flush goroutine (and, if config.BufferDir is
+// set, the replay goroutine) immediately.
+func NewBatchWriter(client *Client, config BatchConfig) *BatchWriter {
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = 1000
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = 500 * time.Millisecond
+	}
+
+	bw := &BatchWriter{
+		client:     client,
+		config:     config,
+		addCh:      make(chan batchPoint, config.MaxBatchSize),
+		flushCh:    make(chan chan struct{}),
+		closeCh:    make(chan struct{}),
+		doneCh:     make(chan struct{}),
+		senderDone: make(chan struct{}),
+	}
+	bw.sendCond = sync.NewCond(&bw.sendMu)
+
+	go bw.run()
+	go bw.sendLoop()
+	if config.BufferDir != "" {
+		go bw.replayLoop()
+	}
+
+	return bw
+}
+
+// Add enqueues series to be written to the optionally given retention
+// policy. It never blocks on network I/O.
+func (self *BatchWriter) Add(series *Series, retentionPolicy ...string) {
+	rp := ""
+	if len(retentionPolicy) > 0 {
+		rp = retentionPolicy[0]
+	}
+	self.addCh <- batchPoint{key: batchKey(rp), retentionPolicy: rp, series: series}
+}
+
+// Flush blocks until all points currently buffered have been written
+// (or spooled).
+func (self *BatchWriter) Flush() {
+	done := make(chan struct{})
+	self.flushCh <- done
+	<-done
+	self.waitForSendDrain()
+}
+
+// Close flushes any buffered points and stops the background
+// goroutines. It is not safe to call Add after Close.
+func (self *BatchWriter) Close() error {
+	self.Flush()
+	close(self.closeCh)
+	<-self.doneCh
+
+	self.sendMu.Lock()
+	self.stopped = true
+	self.sendMu.Unlock()
+	self.sendCond.Broadcast()
+	<-self.senderDone
+
+	return nil
+}
+
+// Metrics returns a snapshot of the writer's cumulative counters.
+func (self *BatchWriter) Metrics() BatchMetrics {
+	self.errMu.Lock()
+	lastErr := self.lastErr
+	self.errMu.Unlock()
+	return BatchMetrics{
+		PointsWritten:  atomic.LoadUint64(&self.pointsWritten),
+		PointsDropped:  atomic.LoadUint64(&self.pointsDropped),
+		BatchesSpooled: atomic.LoadUint64(&self.batchesSpooled),
+		LastError:      lastErr,
+	}
+}
+
+func (self *BatchWriter) setLastErr(err error) {
+	self.errMu.Lock()
+	self.lastErr = err
+	self.errMu.Unlock()
+}
+
+func (self *BatchWriter) run() {
+	defer close(self.doneCh)
+
+	buckets := map[batchKey][]*Series{}
+	pending := 0
+	ticker := time.NewTicker(self.config.FlushInterval)
+	defer ticker.Stop()
+
+	flush := func() {
+		for key, series := range buckets {
+			if len(series) == 0 {
+				continue
+			}
+			self.enqueueSend(string(key), series)
+		}
+		buckets = map[batchKey][]*Series{}
+		pending = 0
+	}
+
+	drainPending := func() {
+		for {
+			select {
+			case p := <-self.addCh:
+				buckets[p.key] = append(buckets[p.key], p.series)
+				pending += len(p.series.Points)
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case p := <-self.addCh:
+			buckets[p.key] = append(buckets[p.key], p.series)
+			pending += len(p.series.Points)
+			if pending >= self.config.MaxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case done := <-self.flushCh:
+			drainPending()
+			flush()
+			close(done)
+		case <-self.closeCh:
+			drainPending()
+			flush()
+			return
+		}
+	}
+}
+
+// enqueueSend hands a completed batch off to sendLoop. It never blocks
+// on network I/O: the queue is an unbounded slice, not a fixed-size
+// channel, so a server outage backing off in sendLoop cannot make
+// run() (and therefore Add) block.
+func (self *BatchWriter) enqueueSend(retentionPolicy string, series []*Series) {
+	self.sendMu.Lock()
+	self.sendQueue = append(self.sendQueue, pendingBatch{retentionPolicy: retentionPolicy, series: series})
+	self.sendMu.Unlock()
+	self.sendCond.Broadcast()
+}
+
+// waitForSendDrain blocks until sendLoop has no queued or in-flight
+// batches left.
+func (self *BatchWriter) waitForSendDrain() {
+	self.sendMu.Lock()
+	for len(self.sendQueue) > 0 || self.inFlight > 0 {
+		self.sendCond.Wait()
+	}
+	self.sendMu.Unlock()
+}
+
+// sendLoop is the only goroutine that calls writeBatch, so it is the
+// only goroutine that ever sleeps through a retry backoff; run() and
+// Add are never blocked by it.
+func (self *BatchWriter) sendLoop() {
+	for {
+		self.sendMu.Lock()
+		for len(self.sendQueue) == 0 {
+			if self.stopped {
+				self.sendMu.Unlock()
+				close(self.senderDone)
+				return
+			}
+			self.sendCond.Wait()
+		}
+		batch := self.sendQueue[0]
+		self.sendQueue = self.sendQueue[1:]
+		self.inFlight++
+		self.sendMu.Unlock()
+
+		self.writeBatch(batch.retentionPolicy, batch.series)
+
+		self.sendMu.Lock()
+		self.inFlight--
+		self.sendMu.Unlock()
+		self.sendCond.Broadcast()
+	}
+}
+
+// writeBatch writes series to the server, retrying transient failures
+// with exponential backoff, and spools the batch to disk if every
+// retry fails.
+func (self *BatchWriter) writeBatch(retentionPolicy string, series []*Series) {
+	backoff := self.config.RetryInterval
+	var err error
+	for attempt := 0; attempt <= self.config.MaxRetries; attempt++ {
+		if retentionPolicy == "" {
+			err = self.client.WriteSeries(series)
+		} else {
+			err = self.client.WriteSeriesWithRetentionPolicy(series, retentionPolicy)
+		}
+		if err == nil {
+			atomic.AddUint64(&self.pointsWritten, uint64(countPoints(series)))
+			return
+		}
+		self.setLastErr(err)
+		if attempt < self.config.MaxRetries {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	if self.config.BufferDir == "" {
+		atomic.AddUint64(&self.pointsDropped, uint64(countPoints(series)))
+		return
+	}
+	if spoolErr := self.spool(retentionPolicy, series); spoolErr != nil {
+		self.setLastErr(spoolErr)
+		atomic.AddUint64(&self.pointsDropped, uint64(countPoints(series)))
+		return
+	}
+	atomic.AddUint64(&self.batchesSpooled, 1)
+}
+
+// countPoints sums the number of data points across series, since a
+// single Series can hold many rows and BatchMetrics counts points, not
+// series.
+func countPoints(series []*Series) int {
+	n := 0
+	for _, s := range series {
+		n += len(s.Points)
+	}
+	return n
+}
+
+type spooledBatch struct {
+	RetentionPolicy string    `json:"retentionPolicy"`
+	Series          []*Series `json:"series"`
+}
+
+// spool writes series to BufferDir as a gzipped JSON file so it can be
+// replayed once the server recovers.
+func (self *BatchWriter) spool(retentionPolicy string, series []*Series) error {
+	if err := os.MkdirAll(self.config.BufferDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(&spooledBatch{RetentionPolicy: retentionPolicy, Series: series})
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%020d.json.gz", time.Now().UnixNano())
+	path := filepath.Join(self.config.BufferDir, name)
+	tmp := path + ".tmp"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write(data); err != nil {
+		gz.Close()
+		f.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// replayLoop periodically attempts to resend batches spooled to
+// BufferDir, oldest first, removing each file once it is written
+// successfully.
+func (self *BatchWriter) replayLoop() {
+	ticker := time.NewTicker(self.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			self.replayOnce()
+		case <-self.closeCh:
+			self.replayOnce()
+			return
+		}
+	}
+}
+
+func (self *BatchWriter) replayOnce() {
+	entries, err := ioutil.ReadDir(self.config.BufferDir)
+	if err != nil {
+		return
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".gz" {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := filepath.Join(self.config.BufferDir, name)
+		if err := self.replayFile(path); err != nil {
+			log.Printf("influxdb: failed to replay spooled batch %s: %s", path, err)
+			return
+		}
+	}
+}
+
+func (self *BatchWriter) replayFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	data, err := ioutil.ReadAll(gz)
+	if err != nil {
+		return err
+	}
+
+	batch := &spooledBatch{}
+	if err := json.Unmarshal(data, batch); err != nil {
+		return err
+	}
+
+	if batch.RetentionPolicy == "" {
+		err = self.client.WriteSeries(batch.Series)
+	} else {
+		err = self.client.WriteSeriesWithRetentionPolicy(batch.Series, batch.RetentionPolicy)
+	}
+	if err != nil {
+		return err
+	}
+
+	atomic.AddUint64(&self.pointsWritten, uint64(countPoints(batch.Series)))
+	return os.Remove(path)
+}
@@ -0,0 +1,98 @@
+package influxdb
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sync"
+)
+
+// ChunkedResponse streams the series of a chunked query result one at a
+// time instead of buffering the whole response in memory. Callers must
+// call Close when done with it.
+type ChunkedResponse struct {
+	body    io.ReadCloser
+	decoder *json.Decoder
+	once    sync.Once
+}
+
+// NextSeries decodes and returns the next series in the stream. It
+// returns io.EOF once the stream is exhausted.
+func (self *ChunkedResponse) NextSeries() (*Series, error) {
+	if !self.decoder.More() {
+		return nil, io.EOF
+	}
+	series := &Series{}
+	if err := self.decoder.Decode(series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// Close releases the underlying HTTP response body. It is safe to call
+// more than once.
+func (self *ChunkedResponse) Close() error {
+	var err error
+	self.once.Do(func() {
+		err = self.body.Close()
+	})
+	return err
+}
+
+// QueryChunked runs query against the server with chunked responses
+// enabled, requesting chunkSize points per chunk, and returns a
+// ChunkedResponse that streams series as they arrive rather than
+// buffering the entire result set in memory.
+func (self *Client) QueryChunked(query string, chunkSize int, precision ...TimePrecision) (*ChunkedResponse, error) {
+	return self.QueryChunkedCtx(context.Background(), query, chunkSize, precision...)
+}
+
+// QueryChunkedCtx is the context-aware variant of QueryChunked.
+func (self *Client) QueryChunkedCtx(ctx context.Context, query string, chunkSize int, precision ...TimePrecision) (*ChunkedResponse, error) {
+	escapedQuery := url.QueryEscape(query)
+	reqUrl := self.getUrl("/db/" + self.database + "/series")
+	if len(precision) > 0 {
+		reqUrl += "&time_precision=" + string(precision[0])
+	}
+	reqUrl += fmt.Sprintf("&chunked=true&chunk_size=%d", chunkSize)
+	reqUrl += "&q=" + escapedQuery
+
+	req, err := self.newRequest(ctx, "GET", reqUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !self.compression {
+		req.Header.Set("Accept-Encoding", "identity")
+	}
+	resp, err := self.httpClient.Do(req)
+	if err := responseToError(resp, err, false); err != nil {
+		return nil, err
+	}
+
+	body := resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(body)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		body = &gzipReadCloser{gzipReader, resp.Body}
+	}
+
+	return &ChunkedResponse{body: body, decoder: json.NewDecoder(body)}, nil
+}
+
+// gzipReadCloser wraps a gzip.Reader together with the underlying HTTP
+// body so Close shuts down both.
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (self *gzipReadCloser) Close() error {
+	self.Reader.Close()
+	return self.body.Close()
+}
@@ -3,6 +3,7 @@ package influxdb
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,6 +15,12 @@ import (
 
 const (
 	UDPMaxMessageSize = 2048
+
+	// Version is the client library version reported in the default
+	// User-Agent header.
+	Version = "0.9.0"
+
+	defaultUserAgent = "influxdb-go/" + Version
 )
 
 type Client struct {
@@ -22,9 +29,10 @@ type Client struct {
 	password    string
 	database    string
 	httpClient  *http.Client
-	udpConn     *net.UDPConn
+	udpPool     *udpConnPool
 	schema      string
 	compression bool
+	userAgent   string
 }
 
 type ClientConfig struct {
@@ -35,6 +43,11 @@ type ClientConfig struct {
 	HttpClient *http.Client
 	IsSecure   bool
 	IsUDP      bool
+
+	// UDP configures the connection pool used by WriteSeriesOverUDP and
+	// WriteSeriesOverUDPWithPrecision. It is ignored unless IsUDP is
+	// set.
+	UDP UDPConfig
 }
 
 var defaults *ClientConfig
@@ -65,13 +78,13 @@ func NewClient(config *ClientConfig) (*Client, error) {
 	if config.HttpClient == nil {
 		config.HttpClient = defaults.HttpClient
 	}
-	var udpConn *net.UDPConn
+	var udpPool *udpConnPool
 	if config.IsUDP {
 		serverAddr, err := net.ResolveUDPAddr("udp", host)
 		if err != nil {
 			return nil, err
 		}
-		udpConn, err = net.DialUDP("udp", nil, serverAddr)
+		udpPool, err = newUDPConnPool(serverAddr, config.UDP)
 		if err != nil {
 			return nil, err
 		}
@@ -81,13 +94,43 @@ func NewClient(config *ClientConfig) (*Client, error) {
 	if config.IsSecure {
 		schema = "https"
 	}
-	return &Client{host, username, password, database, config.HttpClient, udpConn, schema, true}, nil
+	return &Client{
+		host:        host,
+		username:    username,
+		password:    password,
+		database:    database,
+		httpClient:  config.HttpClient,
+		udpPool:     udpPool,
+		schema:      schema,
+		compression: true,
+		userAgent:   defaultUserAgent,
+	}, nil
 }
 
 func (self *Client) DisableCompression() {
 	self.compression = false
 }
 
+// SetUserAgent overrides the User-Agent header sent with every
+// request, so operators can identify traffic from this client (or a
+// specific application using it) in server logs. It defaults to
+// "influxdb-go/<Version>".
+func (self *Client) SetUserAgent(userAgent string) {
+	self.userAgent = userAgent
+}
+
+// newRequest builds an HTTP request bound to ctx with the client's
+// User-Agent header set, so every *Ctx method gets cancellation and
+// identification for free.
+func (self *Client) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", self.userAgent)
+	return req, nil
+}
+
 func (self *Client) getUrl(path string) string {
 	return self.getUrlWithUserAndPass(path, self.username, self.password)
 }
@@ -114,14 +157,34 @@ func responseToError(response *http.Response, err error, closeResponse bool) err
 	return fmt.Errorf("Server returned (%d): %s", response.StatusCode, string(body))
 }
 
-func (self *Client) CreateDatabase(name string) error {
-	url := self.getUrl("/db")
-	payload := map[string]string{"name": name}
+// postJSONCtx marshals payload and POSTs it as application/json, the
+// way every admin-style endpoint (databases, cluster admins, users,
+// ...) in this client does.
+func (self *Client) postJSONCtx(ctx context.Context, url string, payload interface{}) (*http.Response, error) {
 	data, err := json.Marshal(payload)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	resp, err := self.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	req, err := self.newRequest(ctx, "POST", url, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return self.httpClient.Do(req)
+}
+
+func (self *Client) postJSON(url string, payload interface{}) (*http.Response, error) {
+	return self.postJSONCtx(context.Background(), url, payload)
+}
+
+func (self *Client) CreateDatabase(name string) error {
+	return self.CreateDatabaseCtx(context.Background(), name)
+}
+
+func (self *Client) CreateDatabaseCtx(ctx context.Context, name string) error {
+	url := self.getUrl("/db")
+	payload := map[string]string{"name": name}
+	resp, err := self.postJSONCtx(ctx, url, payload)
 	return responseToError(resp, err, true)
 }
 
@@ -130,7 +193,11 @@ func (self *Client) del(url string) (*http.Response, error) {
 }
 
 func (self *Client) delWithBody(url string, body io.Reader) (*http.Response, error) {
-	req, err := http.NewRequest("DELETE", url, body)
+	return self.delWithBodyCtx(context.Background(), url, body)
+}
+
+func (self *Client) delWithBodyCtx(ctx context.Context, url string, body io.Reader) (*http.Response, error) {
+	req, err := self.newRequest(ctx, "DELETE", url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -138,13 +205,25 @@ func (self *Client) delWithBody(url string, body io.Reader) (*http.Response, err
 }
 
 func (self *Client) DeleteDatabase(name string) error {
+	return self.DeleteDatabaseCtx(context.Background(), name)
+}
+
+func (self *Client) DeleteDatabaseCtx(ctx context.Context, name string) error {
 	url := self.getUrl("/db/" + name)
-	resp, err := self.del(url)
+	resp, err := self.delWithBodyCtx(ctx, url, nil)
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) get(url string) ([]byte, error) {
-	resp, err := self.httpClient.Get(url)
+	return self.getCtx(context.Background(), url)
+}
+
+func (self *Client) getCtx(ctx context.Context, url string) ([]byte, error) {
+	req, err := self.newRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := self.httpClient.Do(req)
 	err = responseToError(resp, err, false)
 	if err != nil {
 		return nil, err
@@ -155,7 +234,11 @@ func (self *Client) get(url string) ([]byte, error) {
 }
 
 func (self *Client) listSomething(url string) ([]map[string]interface{}, error) {
-	body, err := self.get(url)
+	return self.listSomethingCtx(context.Background(), url)
+}
+
+func (self *Client) listSomethingCtx(ctx context.Context, url string) ([]map[string]interface{}, error) {
+	body, err := self.getCtx(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -168,50 +251,70 @@ func (self *Client) listSomething(url string) ([]map[string]interface{}, error)
 }
 
 func (self *Client) GetDatabaseList() ([]map[string]interface{}, error) {
+	return self.GetDatabaseListCtx(context.Background())
+}
+
+func (self *Client) GetDatabaseListCtx(ctx context.Context) ([]map[string]interface{}, error) {
 	url := self.getUrl("/db")
-	return self.listSomething(url)
+	return self.listSomethingCtx(ctx, url)
 }
 
 func (self *Client) CreateClusterAdmin(name, password string) error {
+	return self.CreateClusterAdminCtx(context.Background(), name, password)
+}
+
+func (self *Client) CreateClusterAdminCtx(ctx context.Context, name, password string) error {
 	url := self.getUrl("/cluster_admins")
 	payload := map[string]string{"name": name, "password": password}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-	resp, err := self.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	resp, err := self.postJSONCtx(ctx, url, payload)
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) UpdateClusterAdmin(name, password string) error {
+	return self.UpdateClusterAdminCtx(context.Background(), name, password)
+}
+
+func (self *Client) UpdateClusterAdminCtx(ctx context.Context, name, password string) error {
 	url := self.getUrl("/cluster_admins/" + name)
 	payload := map[string]string{"password": password}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-	resp, err := self.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	resp, err := self.postJSONCtx(ctx, url, payload)
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) DeleteClusterAdmin(name string) error {
+	return self.DeleteClusterAdminCtx(context.Background(), name)
+}
+
+func (self *Client) DeleteClusterAdminCtx(ctx context.Context, name string) error {
 	url := self.getUrl("/cluster_admins/" + name)
-	resp, err := self.del(url)
+	resp, err := self.delWithBodyCtx(ctx, url, nil)
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) GetClusterAdminList() ([]map[string]interface{}, error) {
+	return self.GetClusterAdminListCtx(context.Background())
+}
+
+func (self *Client) GetClusterAdminListCtx(ctx context.Context) ([]map[string]interface{}, error) {
 	url := self.getUrl("/cluster_admins")
-	return self.listSomething(url)
+	return self.listSomethingCtx(ctx, url)
 }
 
 func (self *Client) Servers() ([]map[string]interface{}, error) {
+	return self.ServersCtx(context.Background())
+}
+
+func (self *Client) ServersCtx(ctx context.Context) ([]map[string]interface{}, error) {
 	url := self.getUrl("/cluster/servers")
-	return self.listSomething(url)
+	return self.listSomethingCtx(ctx, url)
 }
 
 func (self *Client) RemoveServer(id int) error {
-	resp, err := self.del(self.getUrl(fmt.Sprintf("/cluster/servers/%d", id)))
+	return self.RemoveServerCtx(context.Background(), id)
+}
+
+func (self *Client) RemoveServerCtx(ctx context.Context, id int) error {
+	resp, err := self.delWithBodyCtx(ctx, self.getUrl(fmt.Sprintf("/cluster/servers/%d", id)), nil)
 	return responseToError(resp, err, true)
 }
 
@@ -227,6 +330,12 @@ func (self *Client) RemoveServer(id int) error {
 //     // to the limited time series only
 //     client.CreateDatabaseUser("db", "limited", "pass", "^$", "limited")
 func (self *Client) CreateDatabaseUser(database, name, password string, permissions ...string) error {
+	return self.CreateDatabaseUserCtx(context.Background(), database, name, password, permissions...)
+}
+
+// CreateDatabaseUserCtx is the context-aware variant of
+// CreateDatabaseUser.
+func (self *Client) CreateDatabaseUserCtx(ctx context.Context, database, name, password string, permissions ...string) error {
 	readMatcher, writeMatcher := ".*", ".*"
 	switch len(permissions) {
 	case 0:
@@ -238,28 +347,32 @@ func (self *Client) CreateDatabaseUser(database, name, password string, permissi
 
 	url := self.getUrl("/db/" + database + "/users")
 	payload := map[string]string{"name": name, "password": password, "readFrom": readMatcher, "writeTo": writeMatcher}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-	resp, err := self.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	resp, err := self.postJSONCtx(ctx, url, payload)
 	return responseToError(resp, err, true)
 }
 
 // Change the cluster admin password
 func (self *Client) ChangeClusterAdminPassword(name, newPassword string) error {
+	return self.ChangeClusterAdminPasswordCtx(context.Background(), name, newPassword)
+}
+
+// ChangeClusterAdminPasswordCtx is the context-aware variant of
+// ChangeClusterAdminPassword.
+func (self *Client) ChangeClusterAdminPasswordCtx(ctx context.Context, name, newPassword string) error {
 	url := self.getUrl("/cluster_admins/" + name)
 	payload := map[string]interface{}{"password": newPassword}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-	resp, err := self.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	resp, err := self.postJSONCtx(ctx, url, payload)
 	return responseToError(resp, err, true)
 }
 
 // Change the user password, adming flag and optionally permissions
 func (self *Client) ChangeDatabaseUser(database, name, newPassword string, isAdmin bool, newPermissions ...string) error {
+	return self.ChangeDatabaseUserCtx(context.Background(), database, name, newPassword, isAdmin, newPermissions...)
+}
+
+// ChangeDatabaseUserCtx is the context-aware variant of
+// ChangeDatabaseUser.
+func (self *Client) ChangeDatabaseUserCtx(ctx context.Context, database, name, newPassword string, isAdmin bool, newPermissions ...string) error {
 	switch len(newPermissions) {
 	case 0, 2:
 	default:
@@ -272,17 +385,17 @@ func (self *Client) ChangeDatabaseUser(database, name, newPassword string, isAdm
 		payload["readFrom"] = newPermissions[0]
 		payload["writeTo"] = newPermissions[1]
 	}
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-	resp, err := self.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	resp, err := self.postJSONCtx(ctx, url, payload)
 	return responseToError(resp, err, true)
 }
 
 // See Client.CreateDatabaseUser for more info on the permissions
 // argument
 func (self *Client) updateDatabaseUserCommon(database, name string, password *string, isAdmin *bool, permissions ...string) error {
+	return self.updateDatabaseUserCommonCtx(context.Background(), database, name, password, isAdmin, permissions...)
+}
+
+func (self *Client) updateDatabaseUserCommonCtx(ctx context.Context, database, name string, password *string, isAdmin *bool, permissions ...string) error {
 	url := self.getUrl("/db/" + database + "/users/" + name)
 	payload := map[string]interface{}{}
 	if password != nil {
@@ -299,11 +412,7 @@ func (self *Client) updateDatabaseUserCommon(database, name string, password *st
 	default:
 	}
 
-	data, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-	resp, err := self.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	resp, err := self.postJSONCtx(ctx, url, payload)
 	return responseToError(resp, err, true)
 }
 
@@ -311,25 +420,45 @@ func (self *Client) UpdateDatabaseUser(database, name, password string) error {
 	return self.updateDatabaseUserCommon(database, name, &password, nil)
 }
 
+func (self *Client) UpdateDatabaseUserCtx(ctx context.Context, database, name, password string) error {
+	return self.updateDatabaseUserCommonCtx(ctx, database, name, &password, nil)
+}
+
 func (self *Client) UpdateDatabaseUserPermissions(database, name, readPermission, writePermissions string) error {
 	return self.updateDatabaseUserCommon(database, name, nil, nil, readPermission, writePermissions)
 }
 
+func (self *Client) UpdateDatabaseUserPermissionsCtx(ctx context.Context, database, name, readPermission, writePermissions string) error {
+	return self.updateDatabaseUserCommonCtx(ctx, database, name, nil, nil, readPermission, writePermissions)
+}
+
 func (self *Client) DeleteDatabaseUser(database, name string) error {
+	return self.DeleteDatabaseUserCtx(context.Background(), database, name)
+}
+
+func (self *Client) DeleteDatabaseUserCtx(ctx context.Context, database, name string) error {
 	url := self.getUrl("/db/" + database + "/users/" + name)
-	resp, err := self.del(url)
+	resp, err := self.delWithBodyCtx(ctx, url, nil)
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) GetDatabaseUserList(database string) ([]map[string]interface{}, error) {
+	return self.GetDatabaseUserListCtx(context.Background(), database)
+}
+
+func (self *Client) GetDatabaseUserListCtx(ctx context.Context, database string) ([]map[string]interface{}, error) {
 	url := self.getUrl("/db/" + database + "/users")
-	return self.listSomething(url)
+	return self.listSomethingCtx(ctx, url)
 }
 
 func (self *Client) AlterDatabasePrivilege(database, name string, isAdmin bool, permissions ...string) error {
 	return self.updateDatabaseUserCommon(database, name, nil, &isAdmin, permissions...)
 }
 
+func (self *Client) AlterDatabasePrivilegeCtx(ctx context.Context, database, name string, isAdmin bool, permissions ...string) error {
+	return self.updateDatabaseUserCommonCtx(ctx, database, name, nil, &isAdmin, permissions...)
+}
+
 type TimePrecision string
 
 const (
@@ -342,30 +471,49 @@ func (self *Client) WriteSeries(series []*Series) error {
 	return self.writeSeriesCommon(series, nil)
 }
 
-func (self *Client) WriteSeriesOverUDP(series []*Series) error {
-	data, err := json.Marshal(series)
-	if err != nil {
-		return err
-	}
-	// because max of msg over upd is 2048 bytes
-	// https://github.com/influxdb/influxdb/blob/master/src/api/udp/api.go#L65
-	if len(data) >= UDPMaxMessageSize {
-		err = fmt.Errorf("data size over limit %v limit is %v", len(data), UDPMaxMessageSize)
-		fmt.Println(err)
-		return err
-	}
-	_, err = self.udpConn.Write(data)
-	if err != nil {
-		return err
-	}
-	return nil
+func (self *Client) WriteSeriesCtx(ctx context.Context, series []*Series) error {
+	return self.writeSeriesCommonCtx(ctx, series, nil)
 }
 
 func (self *Client) WriteSeriesWithTimePrecision(series []*Series, timePrecision TimePrecision) error {
 	return self.writeSeriesCommon(series, map[string]string{"time_precision": string(timePrecision)})
 }
 
+// WriteSeriesWithTimePrecisionCtx is the context-aware variant of
+// WriteSeriesWithTimePrecision.
+func (self *Client) WriteSeriesWithTimePrecisionCtx(ctx context.Context, series []*Series, timePrecision TimePrecision) error {
+	return self.writeSeriesCommonCtx(ctx, series, map[string]string{"time_precision": string(timePrecision)})
+}
+
+// WriteSeriesWithRetentionPolicy writes series to the named retention
+// policy instead of the database's default.
+func (self *Client) WriteSeriesWithRetentionPolicy(series []*Series, retentionPolicy string) error {
+	return self.writeSeriesCommon(series, map[string]string{"rp": retentionPolicy})
+}
+
+// WriteSeriesWithRetentionPolicyCtx is the context-aware variant of
+// WriteSeriesWithRetentionPolicy.
+func (self *Client) WriteSeriesWithRetentionPolicyCtx(ctx context.Context, series []*Series, retentionPolicy string) error {
+	return self.writeSeriesCommonCtx(ctx, series, map[string]string{"rp": retentionPolicy})
+}
+
+// WriteSeriesWithTimePrecisionAndRetentionPolicy combines
+// WriteSeriesWithTimePrecision and WriteSeriesWithRetentionPolicy.
+func (self *Client) WriteSeriesWithTimePrecisionAndRetentionPolicy(series []*Series, timePrecision TimePrecision, retentionPolicy string) error {
+	return self.writeSeriesCommon(series, map[string]string{"time_precision": string(timePrecision), "rp": retentionPolicy})
+}
+
+// WriteSeriesWithTimePrecisionAndRetentionPolicyCtx is the
+// context-aware variant of WriteSeriesWithTimePrecisionAndRetentionPolicy.
+func (self *Client) WriteSeriesWithTimePrecisionAndRetentionPolicyCtx(ctx context.Context, series []*Series, timePrecision TimePrecision, retentionPolicy string) error {
+	return self.writeSeriesCommonCtx(ctx, series, map[string]string{"time_precision": string(timePrecision), "rp": retentionPolicy})
+}
+
 func (self *Client) writeSeriesCommon(series []*Series, options map[string]string) error {
+	return self.writeSeriesCommonCtx(context.Background(), series, options)
+}
+
+func (self *Client) writeSeriesCommonCtx(ctx context.Context, series []*Series, options map[string]string) error {
 	data, err := json.Marshal(series)
 	if err != nil {
 		return err
@@ -386,7 +534,7 @@ func (self *Client) writeSeriesCommon(series []*Series, options map[string]strin
 	} else {
 		b = bytes.NewBuffer(data)
 	}
-	req, err := http.NewRequest("POST", url, b)
+	req, err := self.newRequest(ctx, "POST", url, b)
 	if err != nil {
 		return err
 	}
@@ -401,18 +549,30 @@ func (self *Client) Query(query string, precision ...TimePrecision) ([]*Series,
 	return self.queryCommon(query, false, precision...)
 }
 
+func (self *Client) QueryCtx(ctx context.Context, query string, precision ...TimePrecision) ([]*Series, error) {
+	return self.queryCommonCtx(ctx, query, false, precision...)
+}
+
 func (self *Client) QueryWithNumbers(query string, precision ...TimePrecision) ([]*Series, error) {
 	return self.queryCommon(query, true, precision...)
 }
 
+func (self *Client) QueryWithNumbersCtx(ctx context.Context, query string, precision ...TimePrecision) ([]*Series, error) {
+	return self.queryCommonCtx(ctx, query, true, precision...)
+}
+
 func (self *Client) queryCommon(query string, useNumber bool, precision ...TimePrecision) ([]*Series, error) {
+	return self.queryCommonCtx(context.Background(), query, useNumber, precision...)
+}
+
+func (self *Client) queryCommonCtx(ctx context.Context, query string, useNumber bool, precision ...TimePrecision) ([]*Series, error) {
 	escapedQuery := url.QueryEscape(query)
 	url := self.getUrl("/db/" + self.database + "/series")
 	if len(precision) > 0 {
 		url += "&time_precision=" + string(precision[0])
 	}
 	url += "&q=" + escapedQuery
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := self.newRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -442,31 +602,63 @@ func (self *Client) queryCommon(query string, useNumber bool, precision ...TimeP
 }
 
 func (self *Client) Ping() error {
+	return self.PingCtx(context.Background())
+}
+
+func (self *Client) PingCtx(ctx context.Context) error {
 	url := self.getUrl("/ping")
-	resp, err := self.httpClient.Get(url)
+	req, err := self.newRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := self.httpClient.Do(req)
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) AuthenticateDatabaseUser(database, username, password string) error {
+	return self.AuthenticateDatabaseUserCtx(context.Background(), database, username, password)
+}
+
+func (self *Client) AuthenticateDatabaseUserCtx(ctx context.Context, database, username, password string) error {
 	url := self.getUrlWithUserAndPass(fmt.Sprintf("/db/%s/authenticate", database), username, password)
-	resp, err := self.httpClient.Get(url)
+	req, err := self.newRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := self.httpClient.Do(req)
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) AuthenticateClusterAdmin(username, password string) error {
+	return self.AuthenticateClusterAdminCtx(context.Background(), username, password)
+}
+
+func (self *Client) AuthenticateClusterAdminCtx(ctx context.Context, username, password string) error {
 	url := self.getUrlWithUserAndPass("/cluster_admins/authenticate", username, password)
-	resp, err := self.httpClient.Get(url)
+	req, err := self.newRequest(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := self.httpClient.Do(req)
 	return responseToError(resp, err, true)
 }
 
 func (self *Client) GetContinuousQueries() ([]map[string]interface{}, error) {
+	return self.GetContinuousQueriesCtx(context.Background())
+}
+
+func (self *Client) GetContinuousQueriesCtx(ctx context.Context) ([]map[string]interface{}, error) {
 	url := self.getUrlWithUserAndPass(fmt.Sprintf("/db/%s/continuous_queries", self.database), self.username, self.password)
-	return self.listSomething(url)
+	return self.listSomethingCtx(ctx, url)
 }
 
 func (self *Client) DeleteContinuousQueries(id int) error {
+	return self.DeleteContinuousQueriesCtx(context.Background(), id)
+}
+
+func (self *Client) DeleteContinuousQueriesCtx(ctx context.Context, id int) error {
 	url := self.getUrlWithUserAndPass(fmt.Sprintf("/db/%s/continuous_queries/%d", self.database, id), self.username, self.password)
-	resp, err := self.del(url)
+	resp, err := self.delWithBodyCtx(ctx, url, nil)
 	return responseToError(resp, err, true)
 }
 
@@ -483,8 +675,12 @@ type Shard struct {
 }
 
 func (self *Client) GetShards() (*LongTermShortTermShards, error) {
+	return self.GetShardsCtx(context.Background())
+}
+
+func (self *Client) GetShardsCtx(ctx context.Context) (*LongTermShortTermShards, error) {
 	url := self.getUrlWithUserAndPass("/cluster/shards", self.username, self.password)
-	body, err := self.get(url)
+	body, err := self.getCtx(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -498,12 +694,16 @@ func (self *Client) GetShards() (*LongTermShortTermShards, error) {
 }
 
 func (self *Client) DropShard(id uint32, serverIds []uint32) error {
+	return self.DropShardCtx(context.Background(), id, serverIds)
+}
+
+func (self *Client) DropShardCtx(ctx context.Context, id uint32, serverIds []uint32) error {
 	url := self.getUrlWithUserAndPass(fmt.Sprintf("/cluster/shards/%d", id), self.username, self.password)
 	ids := map[string][]uint32{"serverIds": serverIds}
 	body, err := json.Marshal(ids)
 	if err != nil {
 		return err
 	}
-	_, err = self.delWithBody(url, bytes.NewBuffer(body))
+	_, err = self.delWithBodyCtx(ctx, url, bytes.NewBuffer(body))
 	return err
 }
@@ -0,0 +1,182 @@
+package influxdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Point is a single line-protocol measurement: a name, a set of tags
+// (indexed, string-only) and a set of fields (typed values), with an
+// optional timestamp. Unlike Series/WriteSeries, which POST JSON to
+// /db/{name}/series, WriteSeriesLineProtocol POSTs points like this to
+// /write using InfluxDB's line protocol, which is considerably more
+// compact for high-cardinality writes.
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]interface{}
+	Timestamp   time.Time
+}
+
+// WriteSeriesLineProtocol writes points to the server using the line
+// protocol (measurement,tag=val field=val timestamp) rather than the
+// JSON format used by WriteSeries. precision controls both the
+// timestamp unit the server is told to expect and the unit each
+// point's Timestamp is written in; a point with a zero Timestamp is
+// written without a timestamp.
+func (self *Client) WriteSeriesLineProtocol(points []*Point, precision TimePrecision, retentionPolicy ...string) error {
+	return self.WriteSeriesLineProtocolCtx(context.Background(), points, precision, retentionPolicy...)
+}
+
+// WriteSeriesLineProtocolCtx is the context-aware variant of
+// WriteSeriesLineProtocol.
+func (self *Client) WriteSeriesLineProtocolCtx(ctx context.Context, points []*Point, precision TimePrecision, retentionPolicy ...string) error {
+	var buf bytes.Buffer
+	for _, point := range points {
+		if err := writeLine(&buf, point, precision); err != nil {
+			return err
+		}
+	}
+
+	url := self.getUrl("/write") + "&db=" + self.database + "&precision=" + linePrecision(precision)
+	if len(retentionPolicy) > 0 {
+		url += "&rp=" + retentionPolicy[0]
+	}
+
+	var body *bytes.Buffer
+	if self.compression {
+		body = bytes.NewBuffer(nil)
+		w := gzip.NewWriter(body)
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		w.Flush()
+		w.Close()
+	} else {
+		body = &buf
+	}
+
+	req, err := self.newRequest(ctx, "POST", url, body)
+	if err != nil {
+		return err
+	}
+	if self.compression {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	resp, err := self.httpClient.Do(req)
+	return responseToError(resp, err, true)
+}
+
+// linePrecision maps a TimePrecision to the query parameter value the
+// /write endpoint expects, defaulting to nanoseconds when unset.
+func linePrecision(precision TimePrecision) string {
+	switch precision {
+	case Second:
+		return "s"
+	case Millisecond:
+		return "ms"
+	case Microsecond:
+		return "u"
+	default:
+		return "ns"
+	}
+}
+
+func writeLine(buf *bytes.Buffer, point *Point, precision TimePrecision) error {
+	buf.WriteString(escapeLineProtocol(point.Measurement))
+
+	tagKeys := make([]string, 0, len(point.Tags))
+	for k := range point.Tags {
+		tagKeys = append(tagKeys, k)
+	}
+	sort.Strings(tagKeys)
+	for _, k := range tagKeys {
+		buf.WriteByte(',')
+		buf.WriteString(escapeLineProtocol(k))
+		buf.WriteByte('=')
+		buf.WriteString(escapeLineProtocol(point.Tags[k]))
+	}
+
+	if len(point.Fields) == 0 {
+		return fmt.Errorf("influxdb: point for measurement %q has no fields", point.Measurement)
+	}
+	fieldKeys := make([]string, 0, len(point.Fields))
+	for k := range point.Fields {
+		fieldKeys = append(fieldKeys, k)
+	}
+	sort.Strings(fieldKeys)
+	buf.WriteByte(' ')
+	for i, k := range fieldKeys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(escapeLineProtocol(k))
+		buf.WriteByte('=')
+		value, err := formatFieldValue(point.Fields[k])
+		if err != nil {
+			return err
+		}
+		buf.WriteString(value)
+	}
+
+	if !point.Timestamp.IsZero() {
+		buf.WriteByte(' ')
+		buf.WriteString(strconv.FormatInt(timestampForPrecision(point.Timestamp, precision), 10))
+	}
+	buf.WriteByte('\n')
+	return nil
+}
+
+func timestampForPrecision(t time.Time, precision TimePrecision) int64 {
+	switch precision {
+	case Second:
+		return t.Unix()
+	case Millisecond:
+		return t.UnixNano() / int64(time.Millisecond)
+	case Microsecond:
+		return t.UnixNano() / int64(time.Microsecond)
+	default:
+		return t.UnixNano()
+	}
+}
+
+func formatFieldValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return `"` + strings.Replace(v, `"`, `\"`, -1) + `"`, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10) + "i", nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10) + "i", nil
+	case int64:
+		return strconv.FormatInt(v, 10) + "i", nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("influxdb: unsupported field value type %T", value)
+	}
+}
+
+// escapeLineProtocol escapes the characters that are significant to
+// the line protocol grammar (backslashes, commas, spaces and equals
+// signs) in measurement names, tag keys, tag values and field keys.
+// String field values are quoted by formatFieldValue instead.
+func escapeLineProtocol(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`,`, `\,`,
+		` `, `\ `,
+		`=`, `\=`,
+	)
+	return replacer.Replace(s)
+}
@@ -0,0 +1,100 @@
+package influxdb
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestEscapeLineProtocol(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"cpu", "cpu"},
+		{"cpu load", `cpu\ load`},
+		{"cpu,load", `cpu\,load`},
+		{"cpu=load", `cpu\=load`},
+		{`cpu\load`, `cpu\\load`},
+	}
+	for _, tt := range tests {
+		if got := escapeLineProtocol(tt.in); got != tt.want {
+			t.Errorf("escapeLineProtocol(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatFieldValue(t *testing.T) {
+	tests := []struct {
+		in      interface{}
+		want    string
+		wantErr bool
+	}{
+		{"value", `"value"`, false},
+		{`has "quotes"`, `"has \"quotes\""`, false},
+		{true, "true", false},
+		{false, "false", false},
+		{42, "42i", false},
+		{int32(42), "42i", false},
+		{int64(42), "42i", false},
+		{1.5, "1.5", false},
+		{float32(1.5), "1.5", false},
+		{[]int{1}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := formatFieldValue(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("formatFieldValue(%v) expected an error, got nil", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("formatFieldValue(%v) returned error: %s", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("formatFieldValue(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteLine(t *testing.T) {
+	point := &Point{
+		Measurement: "cpu load",
+		Tags:        map[string]string{"host": "server01", "region": "us-west"},
+		Fields:      map[string]interface{}{"value": 0.64},
+	}
+	var buf bytes.Buffer
+	if err := writeLine(&buf, point, Second); err != nil {
+		t.Fatalf("writeLine returned error: %s", err)
+	}
+	want := `cpu\ load,host=server01,region=us-west value=0.64` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeLine() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteLineWithTimestamp(t *testing.T) {
+	point := &Point{
+		Measurement: "cpu",
+		Fields:      map[string]interface{}{"value": 1},
+		Timestamp:   time.Unix(1500000000, 0),
+	}
+	var buf bytes.Buffer
+	if err := writeLine(&buf, point, Second); err != nil {
+		t.Fatalf("writeLine returned error: %s", err)
+	}
+	want := "cpu value=1i 1500000000\n"
+	if got := buf.String(); got != want {
+		t.Errorf("writeLine() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteLineNoFields(t *testing.T) {
+	point := &Point{Measurement: "cpu"}
+	var buf bytes.Buffer
+	if err := writeLine(&buf, point, Second); err == nil {
+		t.Error("writeLine with no fields expected an error, got nil")
+	}
+}
@@ -0,0 +1,187 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy describes how long InfluxDB keeps data for a
+// database and how it is sharded. Duration and ShardGroupDuration are
+// represented as InfluxDB duration literals ("1h", "7d", "INF") on the
+// wire, but as time.Duration in Go.
+type RetentionPolicy struct {
+	Name               string
+	Duration           time.Duration
+	ShardGroupDuration time.Duration
+	ReplicaN           uint32
+	Default            bool
+}
+
+type retentionPolicyJSON struct {
+	Name               string `json:"name"`
+	Duration           string `json:"duration"`
+	ShardGroupDuration string `json:"shardGroupDuration,omitempty"`
+	ReplicaN           uint32 `json:"replicaN"`
+	Default            bool   `json:"default"`
+}
+
+func (self *RetentionPolicy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&retentionPolicyJSON{
+		Name:               self.Name,
+		Duration:           formatRetentionDuration(self.Duration),
+		ShardGroupDuration: formatShardGroupDuration(self.ShardGroupDuration),
+		ReplicaN:           self.ReplicaN,
+		Default:            self.Default,
+	})
+}
+
+func (self *RetentionPolicy) UnmarshalJSON(data []byte) error {
+	rp := &retentionPolicyJSON{}
+	if err := json.Unmarshal(data, rp); err != nil {
+		return err
+	}
+	duration, err := parseRetentionDuration(rp.Duration)
+	if err != nil {
+		return err
+	}
+	shardGroupDuration, err := parseRetentionDuration(rp.ShardGroupDuration)
+	if err != nil {
+		return err
+	}
+	self.Name = rp.Name
+	self.Duration = duration
+	self.ShardGroupDuration = shardGroupDuration
+	self.ReplicaN = rp.ReplicaN
+	self.Default = rp.Default
+	return nil
+}
+
+// formatRetentionDuration converts d to the duration literal InfluxDB
+// expects in retention policy requests, e.g. "1h0m0s" -> "1h",
+// 7*24h -> "7d", and 0 -> "INF" (infinite retention).
+func formatRetentionDuration(d time.Duration) string {
+	return formatDurationLiteral(d, "INF")
+}
+
+// formatShardGroupDuration is like formatRetentionDuration, but a zero
+// duration means "let the server pick a default" rather than
+// "infinite", so it must marshal to "" (and be omitted via
+// the retentionPolicyJSON struct's omitempty tag) instead of "INF".
+func formatShardGroupDuration(d time.Duration) string {
+	return formatDurationLiteral(d, "")
+}
+
+func formatDurationLiteral(d time.Duration, zero string) string {
+	if d == 0 {
+		return zero
+	}
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	// time.Duration.String pads with zero units ("1h0m0s"); drop them so
+	// one hour is written as "1h" and ninety minutes as "1h30m".
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// parseRetentionDuration is the inverse of formatRetentionDuration.
+func parseRetentionDuration(s string) (time.Duration, error) {
+	if s == "" || s == "INF" {
+		return 0, nil
+	}
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid retention policy duration %q: %s", s, err)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func (self *Client) retentionPoliciesUrl(database string) string {
+	return self.getUrl("/db/" + database + "/retention_policies")
+}
+
+func (self *Client) retentionPolicyUrl(database, name string) string {
+	return self.getUrl("/db/" + database + "/retention_policies/" + name)
+}
+
+// CreateRetentionPolicy creates a new retention policy on database.
+func (self *Client) CreateRetentionPolicy(database string, rp *RetentionPolicy) error {
+	return self.CreateRetentionPolicyCtx(context.Background(), database, rp)
+}
+
+// CreateRetentionPolicyCtx is the context-aware variant of
+// CreateRetentionPolicy.
+func (self *Client) CreateRetentionPolicyCtx(ctx context.Context, database string, rp *RetentionPolicy) error {
+	resp, err := self.postJSONCtx(ctx, self.retentionPoliciesUrl(database), rp)
+	return responseToError(resp, err, true)
+}
+
+// AlterRetentionPolicy updates the retention policy named rp.Name on
+// database.
+func (self *Client) AlterRetentionPolicy(database string, rp *RetentionPolicy) error {
+	return self.AlterRetentionPolicyCtx(context.Background(), database, rp)
+}
+
+// AlterRetentionPolicyCtx is the context-aware variant of
+// AlterRetentionPolicy.
+func (self *Client) AlterRetentionPolicyCtx(ctx context.Context, database string, rp *RetentionPolicy) error {
+	resp, err := self.postJSONCtx(ctx, self.retentionPolicyUrl(database, rp.Name), rp)
+	return responseToError(resp, err, true)
+}
+
+// DropRetentionPolicy removes the named retention policy from database.
+func (self *Client) DropRetentionPolicy(database, name string) error {
+	return self.DropRetentionPolicyCtx(context.Background(), database, name)
+}
+
+// DropRetentionPolicyCtx is the context-aware variant of
+// DropRetentionPolicy.
+func (self *Client) DropRetentionPolicyCtx(ctx context.Context, database, name string) error {
+	resp, err := self.delWithBodyCtx(ctx, self.retentionPolicyUrl(database, name), nil)
+	return responseToError(resp, err, true)
+}
+
+// GetRetentionPolicies returns the retention policies defined on
+// database.
+func (self *Client) GetRetentionPolicies(database string) ([]*RetentionPolicy, error) {
+	return self.GetRetentionPoliciesCtx(context.Background(), database)
+}
+
+// GetRetentionPoliciesCtx is the context-aware variant of
+// GetRetentionPolicies.
+func (self *Client) GetRetentionPoliciesCtx(ctx context.Context, database string) ([]*RetentionPolicy, error) {
+	body, err := self.getCtx(ctx, self.retentionPoliciesUrl(database))
+	if err != nil {
+		return nil, err
+	}
+	policies := []*RetentionPolicy{}
+	if err := json.Unmarshal(body, &policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// SetDefaultRetentionPolicy marks the named retention policy as the
+// default for database.
+func (self *Client) SetDefaultRetentionPolicy(database, name string) error {
+	return self.AlterRetentionPolicy(database, &RetentionPolicy{Name: name, Default: true})
+}
+
+// SetDefaultRetentionPolicyCtx is the context-aware variant of
+// SetDefaultRetentionPolicy.
+func (self *Client) SetDefaultRetentionPolicyCtx(ctx context.Context, database, name string) error {
+	return self.AlterRetentionPolicyCtx(ctx, database, &RetentionPolicy{Name: name, Default: true})
+}
@@ -0,0 +1,87 @@
+package influxdb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatRetentionDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "INF"},
+		{time.Hour, "1h"},
+		{24 * time.Hour, "1d"},
+		{7 * 24 * time.Hour, "7d"},
+		{90 * time.Minute, "1h30m"},
+	}
+	for _, tt := range tests {
+		if got := formatRetentionDuration(tt.d); got != tt.want {
+			t.Errorf("formatRetentionDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFormatShardGroupDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, ""},
+		{24 * time.Hour, "1d"},
+		{time.Hour, "1h"},
+	}
+	for _, tt := range tests {
+		if got := formatShardGroupDuration(tt.d); got != tt.want {
+			t.Errorf("formatShardGroupDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestParseRetentionDuration(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+	}{
+		{"", 0},
+		{"INF", 0},
+		{"7d", 7 * 24 * time.Hour},
+		{"1h0m0s", time.Hour},
+		{"1h30m0s", 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		got, err := parseRetentionDuration(tt.s)
+		if err != nil {
+			t.Errorf("parseRetentionDuration(%q) returned error: %s", tt.s, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseRetentionDuration(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParseRetentionDurationInvalid(t *testing.T) {
+	if _, err := parseRetentionDuration("7x"); err == nil {
+		t.Error("parseRetentionDuration(\"7x\") expected an error, got nil")
+	}
+	if _, err := parseRetentionDuration("xd"); err == nil {
+		t.Error("parseRetentionDuration(\"xd\") expected an error, got nil")
+	}
+}
+
+func TestRetentionDurationRoundTrip(t *testing.T) {
+	durations := []time.Duration{0, time.Hour, 24 * time.Hour, 7 * 24 * time.Hour, 90 * time.Minute}
+	for _, d := range durations {
+		s := formatRetentionDuration(d)
+		got, err := parseRetentionDuration(s)
+		if err != nil {
+			t.Errorf("parseRetentionDuration(%q) returned error: %s", s, err)
+			continue
+		}
+		if got != d {
+			t.Errorf("round trip through %q: got %v, want %v", s, got, d)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package influxdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+// UDPConfig tunes the UDP connection pool used by WriteSeriesOverUDP
+// and WriteSeriesOverUDPWithPrecision.
+type UDPConfig struct {
+	// PayloadSize is the maximum number of bytes written in a single
+	// datagram; writes larger than this are split at series
+	// boundaries. Defaults to UDPMaxMessageSize. InfluxDB's UDP
+	// listener is often configured for jumbo frames, so this is
+	// commonly raised to something like 65000.
+	PayloadSize int
+
+	// WriteTimeout, if non-zero, is applied to each datagram write via
+	// SetWriteDeadline.
+	WriteTimeout time.Duration
+
+	// PoolSize is the number of pooled *net.UDPConn used to spread
+	// concurrent writers across sockets instead of serializing them on
+	// one connection. Defaults to 1.
+	PoolSize int
+}
+
+// udpConnPool is a small fixed-size pool of UDP connections, handed
+// out over a buffered channel in the style of fatih/pool.
+type udpConnPool struct {
+	conns        chan *net.UDPConn
+	writeTimeout time.Duration
+	payloadSize  int
+}
+
+func newUDPConnPool(addr *net.UDPAddr, config UDPConfig) (*udpConnPool, error) {
+	size := config.PoolSize
+	if size <= 0 {
+		size = 1
+	}
+
+	pool := &udpConnPool{
+		conns:        make(chan *net.UDPConn, size),
+		writeTimeout: config.WriteTimeout,
+		payloadSize:  config.payloadSize(),
+	}
+	for i := 0; i < size; i++ {
+		conn, err := net.DialUDP("udp", nil, addr)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		pool.conns <- conn
+	}
+	return pool, nil
+}
+
+// write borrows a connection from the pool, writes data to it and
+// returns it to the pool.
+func (self *udpConnPool) write(data []byte) error {
+	conn := <-self.conns
+	defer func() { self.conns <- conn }()
+
+	if self.writeTimeout > 0 {
+		if err := conn.SetWriteDeadline(time.Now().Add(self.writeTimeout)); err != nil {
+			return err
+		}
+	}
+	_, err := conn.Write(data)
+	return err
+}
+
+// Close closes every pooled connection.
+func (self *udpConnPool) Close() error {
+	var err error
+	for {
+		select {
+		case conn := <-self.conns:
+			if closeErr := conn.Close(); closeErr != nil {
+				err = closeErr
+			}
+		default:
+			return err
+		}
+	}
+}
+
+func (self *UDPConfig) payloadSize() int {
+	if self.PayloadSize > 0 {
+		return self.PayloadSize
+	}
+	return UDPMaxMessageSize
+}
+
+// WriteSeriesOverUDP writes series to the server's UDP listener as a
+// bare JSON array, the same shape the /series HTTP endpoint accepts.
+// When the encoding of series would exceed the configured payload
+// size, it is split at series boundaries across multiple datagrams
+// instead of returning an error.
+func (self *Client) WriteSeriesOverUDP(series []*Series) error {
+	datagrams, err := splitUDPDatagrams(series, self.udpPayloadSize())
+	if err != nil {
+		return err
+	}
+	for _, datagram := range datagrams {
+		if err := self.udpPool.write(datagram); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSeriesOverUDPWithPrecision exists for symmetry with the HTTP
+// write path, but InfluxDB's UDP listener has no field for a
+// precision and decodes the same bare series array WriteSeriesOverUDP
+// sends; there is nowhere on the wire to put precision, so this is
+// currently equivalent to WriteSeriesOverUDP. It's kept as its own
+// method so callers that need precision delivered can be switched
+// over without a signature change if a future server version adds
+// support for it.
+func (self *Client) WriteSeriesOverUDPWithPrecision(series []*Series, precision TimePrecision) error {
+	return self.WriteSeriesOverUDP(series)
+}
+
+func (self *Client) udpPayloadSize() int {
+	if self.udpPool != nil {
+		return self.udpPool.payloadSize
+	}
+	return UDPMaxMessageSize
+}
+
+// splitUDPDatagrams packs series into as few bare-array datagrams as
+// possible, never exceeding maxPayload bytes, splitting at series
+// boundaries. A single series whose own encoding exceeds maxPayload is
+// still sent, alone, in an oversized datagram; the client has no
+// visibility into a series' individual points to split further.
+func splitUDPDatagrams(series []*Series, maxPayload int) ([][]byte, error) {
+	var datagrams [][]byte
+	var batch []*Series
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		data, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+		datagrams = append(datagrams, data)
+		batch = nil
+		return nil
+	}
+
+	for _, s := range series {
+		candidate := append(append([]*Series{}, batch...), s)
+		data, err := json.Marshal(candidate)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxPayload && len(batch) > 0 {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			candidate = []*Series{s}
+		}
+		batch = candidate
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if len(datagrams) == 0 {
+		return nil, fmt.Errorf("influxdb: no series to write over UDP")
+	}
+	return datagrams, nil
+}
@@ -0,0 +1,78 @@
+package influxdb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func seriesNamed(name string, numPoints int) *Series {
+	s := &Series{Name: name}
+	for i := 0; i < numPoints; i++ {
+		s.Points = append(s.Points, []interface{}{i})
+	}
+	return s
+}
+
+func TestSplitUDPDatagramsSingleDatagram(t *testing.T) {
+	series := []*Series{seriesNamed("cpu", 1), seriesNamed("mem", 1)}
+	datagrams, err := splitUDPDatagrams(series, UDPMaxMessageSize)
+	if err != nil {
+		t.Fatalf("splitUDPDatagrams returned error: %s", err)
+	}
+	if len(datagrams) != 1 {
+		t.Fatalf("got %d datagrams, want 1", len(datagrams))
+	}
+
+	var got []*Series
+	if err := json.Unmarshal(datagrams[0], &got); err != nil {
+		t.Fatalf("datagram did not decode as a bare series array: %s", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("decoded %d series, want 2", len(got))
+	}
+}
+
+func TestSplitUDPDatagramsBoundarySplit(t *testing.T) {
+	series := []*Series{seriesNamed("cpu", 1), seriesNamed("mem", 1), seriesNamed("disk", 1)}
+
+	data, err := json.Marshal([]*Series{series[0]})
+	if err != nil {
+		t.Fatalf("json.Marshal returned error: %s", err)
+	}
+	maxPayload := len(data) + 1
+
+	datagrams, err := splitUDPDatagrams(series, maxPayload)
+	if err != nil {
+		t.Fatalf("splitUDPDatagrams returned error: %s", err)
+	}
+	if len(datagrams) != 3 {
+		t.Fatalf("got %d datagrams, want 3 (one series per datagram)", len(datagrams))
+	}
+
+	for i, d := range datagrams {
+		var got []*Series
+		if err := json.Unmarshal(d, &got); err != nil {
+			t.Fatalf("datagram %d did not decode as a bare series array: %s", i, err)
+		}
+		if len(got) != 1 || got[0].Name != series[i].Name {
+			t.Errorf("datagram %d = %+v, want a single series named %q", i, got, series[i].Name)
+		}
+	}
+}
+
+func TestSplitUDPDatagramsOversizedSingleSeries(t *testing.T) {
+	series := []*Series{seriesNamed("cpu", 1)}
+	datagrams, err := splitUDPDatagrams(series, 1)
+	if err != nil {
+		t.Fatalf("splitUDPDatagrams returned error: %s", err)
+	}
+	if len(datagrams) != 1 {
+		t.Fatalf("got %d datagrams, want 1 (oversized series still sent alone)", len(datagrams))
+	}
+}
+
+func TestSplitUDPDatagramsEmpty(t *testing.T) {
+	if _, err := splitUDPDatagrams(nil, UDPMaxMessageSize); err == nil {
+		t.Error("splitUDPDatagrams(nil, ...) expected an error, got nil")
+	}
+}